@@ -0,0 +1,132 @@
+package gograpple
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/foomo/squadron"
+	"github.com/sirupsen/logrus"
+)
+
+// ContainerBuilder abstracts the tool used to inspect, build, tag and push
+// the patched image, so Patch isn't locked to the docker cli.
+type ContainerBuilder interface {
+	// Platform returns image's platform as "os/architecture". Each backend
+	// inspects under its own JSON shape internally, since buildah's inspect
+	// output doesn't mirror docker's top-level .Os/.Architecture fields.
+	Platform(ctx context.Context, image string) (string, error)
+	// Build builds dir using dockerfile for platform, tagging the result as
+	// tag and passing buildArgs as --build-arg KEY=VALUE pairs.
+	Build(ctx context.Context, dir, dockerfile, tag, platform string, buildArgs map[string]string) (string, error)
+	Push(ctx context.Context, image, tag string) (string, error)
+	Tag(ctx context.Context, source, target string) (string, error)
+}
+
+const (
+	builderDocker  = "docker"
+	builderPodman  = "podman"
+	builderBuildah = "buildah"
+)
+
+// NewContainerBuilder resolves name ("docker", "podman" or "buildah") to a
+// ContainerBuilder backed by that binary on $PATH. An empty name falls back
+// to DetectBuilder.
+func NewContainerBuilder(l *logrus.Entry, name string) (ContainerBuilder, error) {
+	if name == "" {
+		name = DetectBuilder()
+	}
+	bin, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("builder %q not found on $PATH: %w", name, err)
+	}
+	base := cliBuilder{l: l, bin: bin}
+	switch name {
+	case builderDocker:
+		return &dockerBuilder{base}, nil
+	case builderPodman:
+		return &podmanBuilder{base}, nil
+	case builderBuildah:
+		return &buildahBuilder{base}, nil
+	default:
+		return nil, fmt.Errorf("unknown builder %q, expected one of docker, podman, buildah", name)
+	}
+}
+
+// DetectBuilder returns the first builder cli found on $PATH, preferring
+// docker for backwards compatibility and falling back to podman then buildah
+// for rootless Linux and Docker Desktop-less macOS setups.
+func DetectBuilder() string {
+	for _, name := range []string{builderDocker, builderPodman, builderBuildah} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return builderDocker
+}
+
+// AvailableBuilders lists the builder clis found on $PATH, in detection order.
+func AvailableBuilders() []string {
+	var available []string
+	for _, name := range []string{builderDocker, builderPodman, builderBuildah} {
+		if _, err := exec.LookPath(name); err == nil {
+			available = append(available, name)
+		}
+	}
+	return available
+}
+
+func buildArgFlags(buildArgs map[string]string) []string {
+	var flags []string
+	for k, v := range buildArgs {
+		flags = append(flags, "--build-arg", fmt.Sprintf("%v=%v", k, v))
+	}
+	return flags
+}
+
+// cliBuilder implements the docker-compatible subset of ContainerBuilder
+// shared by docker and podman.
+type cliBuilder struct {
+	l   *logrus.Entry
+	bin string
+}
+
+func (b *cliBuilder) Platform(ctx context.Context, image string) (string, error) {
+	return squadron.Command(b.l, b.bin, "image", "inspect", "-f", "{{.Os}}/{{.Architecture}}", image).Run(ctx)
+}
+
+func (b *cliBuilder) Build(ctx context.Context, dir, dockerfile, tag, platform string, buildArgs map[string]string) (string, error) {
+	cmd := append([]string{b.bin, "build", dir, "-f", dockerfile, "-t", tag, "--platform", platform}, buildArgFlags(buildArgs)...)
+	return squadron.Command(b.l, cmd...).Run(ctx)
+}
+
+func (b *cliBuilder) Push(ctx context.Context, image, tag string) (string, error) {
+	return squadron.Command(b.l, b.bin, "push", fmt.Sprintf("%v:%v", image, tag)).Run(ctx)
+}
+
+func (b *cliBuilder) Tag(ctx context.Context, source, target string) (string, error) {
+	return squadron.Command(b.l, b.bin, "tag", source, target).Run(ctx)
+}
+
+// dockerBuilder drives the docker cli.
+type dockerBuilder struct{ cliBuilder }
+
+// podmanBuilder drives the podman cli, which mirrors docker's subcommands
+// closely enough to reuse cliBuilder as-is.
+type podmanBuilder struct{ cliBuilder }
+
+// buildahBuilder drives buildah, which splits build across a separate "bud"
+// subcommand and has no "image" subcommand namespace for inspect.
+type buildahBuilder struct{ cliBuilder }
+
+// Platform queries buildah's own inspect shape: unlike docker/podman, the
+// os/architecture fields live under the nested OCIv1 image spec rather than
+// at the top level.
+func (b *buildahBuilder) Platform(ctx context.Context, image string) (string, error) {
+	return squadron.Command(b.l, b.bin, "inspect", "-f", "{{.OCIv1.Os}}/{{.OCIv1.Architecture}}", image).Run(ctx)
+}
+
+func (b *buildahBuilder) Build(ctx context.Context, dir, dockerfile, tag, platform string, buildArgs map[string]string) (string, error) {
+	cmd := append([]string{b.bin, "bud", dir, "-f", dockerfile, "-t", tag, "--platform", platform}, buildArgFlags(buildArgs)...)
+	return squadron.Command(b.l, cmd...).Run(ctx)
+}
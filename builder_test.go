@@ -0,0 +1,43 @@
+package gograpple
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestBuildArgFlags(t *testing.T) {
+	flags := buildArgFlags(map[string]string{"IMAGE": "myapp:v1", "DEBUG": "1"})
+
+	var pairs []string
+	for i := 0; i < len(flags); i += 2 {
+		if flags[i] != "--build-arg" {
+			t.Fatalf("expected every even index to be --build-arg, got %v at %v", flags[i], i)
+		}
+		pairs = append(pairs, flags[i+1])
+	}
+	sort.Strings(pairs)
+	want := []string{"DEBUG=1", "IMAGE=myapp:v1"}
+	if len(pairs) != len(want) {
+		t.Fatalf("expected %v pairs, got %v", want, pairs)
+	}
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Fatalf("expected pairs %v, got %v", want, pairs)
+		}
+	}
+}
+
+func TestBuildArgFlagsEmpty(t *testing.T) {
+	if flags := buildArgFlags(nil); flags != nil {
+		t.Fatalf("expected nil flags for nil buildArgs, got %v", flags)
+	}
+}
+
+func TestDetectBuilderDefaultsToDocker(t *testing.T) {
+	// On a $PATH with none of docker/podman/buildah installed, DetectBuilder
+	// should still return a usable default rather than an empty string.
+	t.Setenv("PATH", "")
+	if got := DetectBuilder(); got != builderDocker {
+		t.Fatalf("expected DetectBuilder to default to %v, got %v", builderDocker, got)
+	}
+}
@@ -26,6 +26,16 @@ type Config struct {
 	ListenAddr    string `yaml:"listen_addr,omitempty"`
 	DelveContinue bool   `yaml:"delve_continue"`
 	Image         string `yaml:"image,omitempty"`
+	Builder       string `yaml:"builder,omitempty"`
+	// KubectlFallback selects the kubectl-shelling KubeCmd over the client-go
+	// one (see NewKubeCmd), for clusters this process can't reach with a
+	// rest.Config. It only covers the Patch/Rollback surface KubeCmd serves;
+	// Debug and Watch always talk to the cluster directly via kube.Client and
+	// ignore this setting.
+	KubectlFallback bool   `yaml:"kubectl_fallback"`
+	Mode            string `yaml:"mode,omitempty"`
+	Watch           bool   `yaml:"watch"`
+	WatchDebounce   string `yaml:"watch_debounce,omitempty" depends:"Watch"`
 }
 
 func (c Config) MarshalYAML() (interface{}, error) {
@@ -107,6 +117,30 @@ func (c Config) PlatformSuggest(d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{{Text: "linux/amd64"}, {Text: "linux/arm64"}}
 }
 
+func (c Config) KubectlFallbackSuggest(d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{{Text: "true"}, {Text: "false"}}
+}
+
+func (c Config) ModeSuggest(d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{{Text: ModePatch}, {Text: ModeEphemeral}}
+}
+
+func (c Config) WatchSuggest(d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{{Text: "true"}, {Text: "false"}}
+}
+
+func (c Config) WatchDebounceSuggest(d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{{Text: "500ms"}}
+}
+
+func (c Config) BuilderSuggest(d prompt.Document) []prompt.Suggest {
+	var suggestions []prompt.Suggest
+	for _, name := range AvailableBuilders() {
+		suggestions = append(suggestions, prompt.Suggest{Text: name})
+	}
+	return suggestions
+}
+
 func (c Config) ImageSuggest(d prompt.Document) []prompt.Suggest {
 	suggestions := suggest.Completer(d, suggest.MustList(func() ([]string, error) {
 		return kubectl.ListImages(c.Namespace, c.Deployment)
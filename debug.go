@@ -0,0 +1,52 @@
+package gograpple
+
+import (
+	"context"
+
+	"github.com/foomo/gograpple/kube"
+)
+
+const (
+	defaultEphemeralContainerName = "gograpple-debug"
+	defaultDelvePort              = 2345
+
+	// ModePatch mutates the Deployment spec to run the delve-instrumented
+	// image, and requires a Rollback to undo (see Grapple.Patch).
+	ModePatch = "patch"
+	// ModeEphemeral attaches a delve-instrumented ephemeral container to an
+	// already running pod, and is cleaned up by deleting that pod (see
+	// Grapple.Debug).
+	ModeEphemeral = "ephemeral"
+)
+
+// Debug attaches a Delve-equipped ephemeral container to container of pod,
+// sharing its process namespace so Delve can attach to the running binary
+// by PID, without restarting or modifying the Deployment spec. Cleanup is
+// just deleting pod: unlike Patch, there's no rollback bookkeeping or
+// configmap involved, since the ephemeral container is never persisted to
+// the Deployment. The returned Stopper tears down the Delve port-forward;
+// it's up to the caller to stop it on Ctrl-C or when the session ends.
+//
+// Unlike Patch, Debug always talks to the cluster through g.kube (client-go)
+// directly; it has no kubectl-shelling equivalent, so kubectl_fallback has no
+// effect on it.
+func (g Grapple) Debug(ctx context.Context, pod, container, image string) (kube.Stopper, error) {
+	name := defaultEphemeralContainerName
+	g.l.Infof("attaching debug container %v to pod %v, targeting %v", name, pod, container)
+	if err := g.kube.AddEphemeralContainer(ctx, pod, name, image, container); err != nil {
+		return nil, err
+	}
+	g.l.Infof("waiting for debug container to become ready")
+	if _, err := g.kube.WaitForEphemeralContainerRunning(pod, name, defaultWaitTimeout).Run(ctx); err != nil {
+		return nil, err
+	}
+	g.l.Infof("exposing delve on %v", g.listenAddr)
+	return g.kube.PortForward(ctx, pod, g.listenAddr, defaultDelvePort)
+}
+
+// StopDebug tears down a Debug session by deleting pod, taking the attached
+// ephemeral container with it.
+func (g Grapple) StopDebug(ctx context.Context, pod string) error {
+	g.l.Infof("deleting debug pod %v", pod)
+	return g.kube.DeletePod(ctx, pod)
+}
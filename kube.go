@@ -1,6 +1,7 @@
 package gograpple
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -28,32 +29,6 @@ func waitForRollout(l *logrus.Entry, deployment, namespace, timeout string) *squ
 	return squadron.Command(l, cmd...)
 }
 
-func GetMostRecentPodBySelectors(l *logrus.Entry, selectors map[string]string, namespace string) (string, error) {
-	var selector []string
-	for k, v := range selectors {
-		selector = append(selector, fmt.Sprintf("%v=%v", k, v))
-	}
-	cmd := []string{
-		"kubectl", "-n", namespace,
-		"--selector", strings.Join(selector, ","),
-		"get", "pods", "--sort-by=.status.startTime",
-		"-o", "name",
-	}
-	out, err := squadron.Command(l, cmd...).Run()
-	if err != nil {
-		return "", err
-	}
-
-	pods, err := parseResources(out, "\n", "pod/")
-	if err != nil {
-		return "", err
-	}
-	if len(pods) > 0 {
-		return pods[len(pods)-1], nil
-	}
-	return "", fmt.Errorf("no pods found")
-}
-
 func waitForPodState(l *logrus.Entry, namepsace, pod, condition, timeout string) *squadron.Cmd {
 	cmd := []string{
 		"kubectl", "-n", namepsace,
@@ -104,36 +79,13 @@ func execPod(l *logrus.Entry, pod, container, namespace string, cmd []string) *s
 	return squadron.Command(l, c...)
 }
 
-func exposePod(l *logrus.Entry, namespace, pod string, host string, port int) *squadron.Cmd {
-	if host == "127.0.0.1" {
-		host = ""
-	}
-	cmd := []string{
-		"kubectl", "-n", namespace,
-		"expose", "pod", pod,
-		"--type=LoadBalancer",
-		fmt.Sprintf("--port=%v", port),
-		fmt.Sprintf("--external-ip=%v", host),
-		// fmt.Sprintf("--name=%v-%v", pod, port),
-	}
-	return squadron.Command(l, cmd...)
-}
-
-func deleteService(l *logrus.Entry, deployment *v1.Deployment, service string) *squadron.Cmd {
-	cmd := []string{
-		"kubectl", "-n", deployment.Namespace,
-		"delete", "service", service,
-	}
-	return squadron.Command(l, cmd...)
-}
-
-func GetDeployment(l *logrus.Entry, namespace, deployment string) (*v1.Deployment, error) {
+func GetDeployment(ctx context.Context, l *logrus.Entry, namespace, deployment string) (*v1.Deployment, error) {
 	cmd := []string{
 		"kubectl", "-n", namespace,
 		"get", "deployment", deployment,
 		"-o", "json",
 	}
-	out, err := squadron.Command(l, cmd...).Run()
+	out, err := squadron.Command(l, cmd...).Run(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -144,13 +96,13 @@ func GetDeployment(l *logrus.Entry, namespace, deployment string) (*v1.Deploymen
 	return &d, nil
 }
 
-func getNamespaces(l *logrus.Entry) ([]string, error) {
+func getNamespaces(ctx context.Context, l *logrus.Entry) ([]string, error) {
 	cmd := []string{
 		"kubectl",
 		"get", "namespace",
 		"-o", "name",
 	}
-	out, err := squadron.Command(l, cmd...).Run()
+	out, err := squadron.Command(l, cmd...).Run(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -158,13 +110,13 @@ func getNamespaces(l *logrus.Entry) ([]string, error) {
 	return parseResources(out, "\n", "namespace/")
 }
 
-func getDeployments(l *logrus.Entry, namespace string) ([]string, error) {
+func getDeployments(ctx context.Context, l *logrus.Entry, namespace string) ([]string, error) {
 	cmd := []string{
 		"kubectl", "-n", namespace,
 		"get", "deployment",
 		"-o", "name",
 	}
-	out, err := squadron.Command(l, cmd...).Run()
+	out, err := squadron.Command(l, cmd...).Run(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +124,7 @@ func getDeployments(l *logrus.Entry, namespace string) ([]string, error) {
 	return parseResources(out, "\n", "deployment.apps/")
 }
 
-func getPods(l *logrus.Entry, namespace string, selectors map[string]string) ([]string, error) {
+func getPods(ctx context.Context, l *logrus.Entry, namespace string, selectors map[string]string) ([]string, error) {
 	var selector []string
 	for k, v := range selectors {
 		selector = append(selector, fmt.Sprintf("%v=%v", k, v))
@@ -183,7 +135,7 @@ func getPods(l *logrus.Entry, namespace string, selectors map[string]string) ([]
 		"get", "pods", "--sort-by=.status.startTime",
 		"-o", "name",
 	}
-	out, err := squadron.Command(l, cmd...).Run()
+	out, err := squadron.Command(l, cmd...).Run(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +151,7 @@ func getContainers(l *logrus.Entry, deployment *v1.Deployment) []string {
 	return containers
 }
 
-func getPodsByLabels(l *logrus.Entry, labels []string) ([]string, error) {
+func getPodsByLabels(ctx context.Context, l *logrus.Entry, labels []string) ([]string, error) {
 	var selector []string
 	for k, v := range labels {
 		selector = append(selector, fmt.Sprintf("%v=%v", k, v))
@@ -209,7 +161,7 @@ func getPodsByLabels(l *logrus.Entry, labels []string) ([]string, error) {
 		"-l", strings.Join(labels, ","),
 		"-o", "name", "-A",
 	}
-	out, err := squadron.Command(l, cmd...).Run()
+	out, err := squadron.Command(l, cmd...).Run(ctx)
 	if err != nil {
 		return nil, err
 	}
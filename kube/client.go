@@ -0,0 +1,495 @@
+// Package kube talks to the Kubernetes API server directly via client-go,
+// replacing the kubectl subprocess shell-outs in the root package for the
+// operations on the hot path of grapple patch/debug.
+package kube
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Cmd wraps a single client-go operation so call sites can log and run it
+// the same way they ran a squadron.Cmd, e.g. `kubeCmd.PatchDeployment(...).Run(ctx)`.
+type Cmd struct {
+	l    *logrus.Entry
+	name string
+	fn   func(ctx context.Context) (string, error)
+}
+
+func (c *Cmd) Run(ctx context.Context) (string, error) {
+	c.l.Debugf("running %v", c.name)
+	out, err := c.fn(ctx)
+	if err != nil {
+		return out, fmt.Errorf("%v: %w", c.name, err)
+	}
+	return out, nil
+}
+
+// Client is a thin, context-aware wrapper around client-go, used in place of
+// shelling out to kubectl. Callers that can't reach the cluster with an
+// in-cluster or kubeconfig-based rest.Config can fall back to kubectlCmd
+// (kubecmd.go) instead, selected via NewKubeCmd.
+type Client struct {
+	l          *logrus.Entry
+	namespace  string
+	clientset  kubernetes.Interface
+	restConfig *rest.Config
+}
+
+// NewClient builds a Client for namespace from the default kubeconfig
+// loading rules (KUBECONFIG env var, then ~/.kube/config), using context
+// cluster as the active context.
+func NewClient(l *logrus.Entry, cluster, namespace string) (*Client, error) {
+	overrides := &clientcmd.ConfigOverrides{}
+	if cluster != "" {
+		overrides.CurrentContext = cluster
+	}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kube config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{l: l, namespace: namespace, clientset: clientset, restConfig: restConfig}, nil
+}
+
+// NewCmd builds a Cmd from a raw operation, for callers outside this package
+// that need to satisfy the same Run(ctx) surface as Client's methods (see
+// the kubectl-fallback implementation in the root package).
+func NewCmd(l *logrus.Entry, name string, fn func(ctx context.Context) (string, error)) *Cmd {
+	return &Cmd{l: l, name: name, fn: fn}
+}
+
+func (c *Client) cmd(name string, fn func(ctx context.Context) (string, error)) *Cmd {
+	return NewCmd(c.l, name, fn)
+}
+
+func (c *Client) GetDeployment(ctx context.Context, deployment string) (*appsv1.Deployment, error) {
+	return c.clientset.AppsV1().Deployments(c.namespace).Get(ctx, deployment, metav1.GetOptions{})
+}
+
+func (c *Client) GetPod(ctx context.Context, pod string) (*corev1.Pod, error) {
+	return c.clientset.CoreV1().Pods(c.namespace).Get(ctx, pod, metav1.GetOptions{})
+}
+
+func (c *Client) DeletePod(ctx context.Context, pod string) error {
+	return c.clientset.CoreV1().Pods(c.namespace).Delete(ctx, pod, metav1.DeleteOptions{})
+}
+
+// AddEphemeralContainer attaches an ephemeral container named name running
+// image to pod via the ephemeralcontainers subresource, targeting the PID
+// namespace of target so tools like Delve can attach to its running
+// process. Unlike regular containers, ephemeral containers can't be removed
+// without deleting the pod.
+func (c *Client) AddEphemeralContainer(ctx context.Context, pod, name, image, target string) error {
+	p, err := c.GetPod(ctx, pod)
+	if err != nil {
+		return err
+	}
+	for _, ec := range p.Spec.EphemeralContainers {
+		if ec.Name == name {
+			return nil
+		}
+	}
+	p.Spec.EphemeralContainers = append(p.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    image,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			Stdin:                    true,
+			TTY:                      false,
+		},
+		TargetContainerName: target,
+	})
+	bs, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = c.clientset.CoreV1().Pods(c.namespace).Patch(
+		ctx, pod, types.StrategicMergePatchType, bs, metav1.PatchOptions{}, "ephemeralcontainers")
+	return err
+}
+
+func (c *Client) GetLatestRevision(ctx context.Context, deployment string) (int, error) {
+	d, err := c.GetDeployment(ctx, deployment)
+	if err != nil {
+		return 0, err
+	}
+	revision, err := strconv.Atoi(d.Annotations[revisionAnnotation])
+	if err != nil {
+		return 0, fmt.Errorf("reading revision annotation of deployment %v: %w", deployment, err)
+	}
+	return revision, nil
+}
+
+// ValidateContainer defaults container to the deployment's sole container
+// if unset, erroring if the deployment has more than one, or checks that
+// container is one of the deployment's containers otherwise.
+func (c *Client) ValidateContainer(d *appsv1.Deployment, container *string) error {
+	var names []string
+	for _, co := range d.Spec.Template.Spec.Containers {
+		names = append(names, co.Name)
+	}
+	if *container == "" {
+		if len(names) != 1 {
+			return fmt.Errorf("deployment %v has multiple containers %v, please specify one", d.Name, names)
+		}
+		*container = names[0]
+		return nil
+	}
+	for _, name := range names {
+		if name == *container {
+			return nil
+		}
+	}
+	return fmt.Errorf("container %v not found in deployment %v, available: %v", *container, d.Name, names)
+}
+
+func (c *Client) PatchDeployment(patch, deployment string) *Cmd {
+	return c.cmd("patch deployment", func(ctx context.Context) (string, error) {
+		d, err := c.clientset.AppsV1().Deployments(c.namespace).Patch(
+			ctx, deployment, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+		if err != nil {
+			return "", err
+		}
+		return d.Name, nil
+	})
+}
+
+func (c *Client) RolloutUndo(deployment string, toRevision int) *Cmd {
+	return c.cmd("rollout undo", func(ctx context.Context) (string, error) {
+		rsList, err := c.clientset.AppsV1().ReplicaSets(c.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, rs := range rsList.Items {
+			if !isOwnedByDeployment(&rs, deployment) || rs.Annotations[revisionAnnotation] != strconv.Itoa(toRevision) {
+				continue
+			}
+			patch := struct {
+				Spec struct {
+					Template corev1.PodTemplateSpec `json:"template"`
+				} `json:"spec"`
+			}{}
+			patch.Spec.Template = rs.Spec.Template
+			bs, err := json.Marshal(patch)
+			if err != nil {
+				return "", err
+			}
+			return c.PatchDeployment(string(bs), deployment).Run(ctx)
+		}
+		return "", fmt.Errorf("no replicaset found for deployment %v at revision %v", deployment, toRevision)
+	})
+}
+
+func (c *Client) UpdateChangeCause(deployment, cause string) *Cmd {
+	return c.cmd("update change-cause", func(ctx context.Context) (string, error) {
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{"kubernetes.io/change-cause":%q}}}`, cause)
+		d, err := c.clientset.AppsV1().Deployments(c.namespace).Patch(
+			ctx, deployment, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+		if err != nil {
+			return "", err
+		}
+		return d.Name, nil
+	})
+}
+
+func (c *Client) DeleteConfigMap(name string) *Cmd {
+	return c.cmd("delete configmap", func(ctx context.Context) (string, error) {
+		err := c.clientset.CoreV1().ConfigMaps(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	})
+}
+
+func (c *Client) CreateConfigMap(name string, data map[string]string) *Cmd {
+	return c.cmd("create configmap", func(ctx context.Context) (string, error) {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: c.namespace},
+			Data:       data,
+		}
+		created, err := c.clientset.CoreV1().ConfigMaps(c.namespace).Create(ctx, cm, metav1.CreateOptions{})
+		if err != nil {
+			return "", err
+		}
+		return created.Name, nil
+	})
+}
+
+// WaitForRollout blocks, via Watch rather than polling, until deployment
+// reports all replicas updated and available, or timeout elapses.
+func (c *Client) WaitForRollout(deployment, timeout string) *Cmd {
+	return c.cmd("wait for rollout", func(ctx context.Context) (string, error) {
+		d, err := c.waitForCondition(ctx, timeout, func(w watch.Interface) (bool, error) {
+			for {
+				select {
+				case event, ok := <-w.ResultChan():
+					if !ok {
+						return false, fmt.Errorf("watch closed before rollout completed")
+					}
+					d, ok := event.Object.(*appsv1.Deployment)
+					if !ok || d.Name != deployment {
+						continue
+					}
+					if deploymentRolloutComplete(d) {
+						return true, nil
+					}
+				}
+			}
+		}, func(ctx context.Context) (watch.Interface, error) {
+			return c.clientset.AppsV1().Deployments(c.namespace).Watch(ctx, metav1.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("metadata.name", deployment).String(),
+			})
+		})
+		if err != nil {
+			return "", err
+		}
+		return d, nil
+	})
+}
+
+func deploymentRolloutComplete(d *appsv1.Deployment) bool {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false
+	}
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas == desired &&
+		d.Status.Replicas == desired &&
+		d.Status.AvailableReplicas == desired
+}
+
+// WaitForPodState blocks, via Watch, until pod satisfies condition
+// ("Ready", "ContainersReady", ...) or timeout elapses.
+func (c *Client) WaitForPodState(pod, condition, timeout string) *Cmd {
+	return c.cmd("wait for pod state", func(ctx context.Context) (string, error) {
+		return c.waitForCondition(ctx, timeout, func(w watch.Interface) (bool, error) {
+			for event := range w.ResultChan() {
+				p, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				for _, cond := range p.Status.Conditions {
+					if string(cond.Type) == condition && cond.Status == corev1.ConditionTrue {
+						return true, nil
+					}
+				}
+			}
+			return false, fmt.Errorf("watch closed before pod reached state %v", condition)
+		}, func(ctx context.Context) (watch.Interface, error) {
+			return c.clientset.CoreV1().Pods(c.namespace).Watch(ctx, metav1.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("metadata.name", pod).String(),
+			})
+		})
+	})
+}
+
+// WaitForEphemeralContainerRunning blocks, via Watch, until pod's ephemeral
+// container named name reports a Running state, or timeout elapses.
+// Ephemeral containers don't factor into the pod's Ready/ContainersReady
+// conditions, so WaitForPodState can't be used to tell whether one has
+// actually started.
+func (c *Client) WaitForEphemeralContainerRunning(pod, name, timeout string) *Cmd {
+	return c.cmd("wait for ephemeral container running", func(ctx context.Context) (string, error) {
+		return c.waitForCondition(ctx, timeout, func(w watch.Interface) (bool, error) {
+			for event := range w.ResultChan() {
+				p, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				for _, ecs := range p.Status.EphemeralContainerStatuses {
+					if ecs.Name == name && ecs.State.Running != nil {
+						return true, nil
+					}
+				}
+			}
+			return false, fmt.Errorf("watch closed before ephemeral container %v started running", name)
+		}, func(ctx context.Context) (watch.Interface, error) {
+			return c.clientset.CoreV1().Pods(c.namespace).Watch(ctx, metav1.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("metadata.name", pod).String(),
+			})
+		})
+	})
+}
+
+func (c *Client) waitForCondition(ctx context.Context, timeout string,
+	check func(watch.Interface) (bool, error), watcher func(context.Context) (watch.Interface, error)) (string, error) {
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return "", fmt.Errorf("parsing timeout %v: %w", timeout, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	w, err := watcher(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer w.Stop()
+	if ok, err := check(w); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("timed out after %v", timeout)
+		}
+		return "", err
+	} else if ok {
+		return "ok", nil
+	}
+	return "", fmt.Errorf("condition not met")
+}
+
+// ListPods returns the names of pods in the namespace matching selectors,
+// sorted by creation time, oldest first.
+func (c *Client) ListPods(ctx context.Context, selectors map[string]string) ([]string, error) {
+	list, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector(selectors),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].CreationTimestamp.Before(&list.Items[j].CreationTimestamp)
+	})
+	var pods []string
+	for _, p := range list.Items {
+		pods = append(pods, p.Name)
+	}
+	return pods, nil
+}
+
+// ListNamespaces returns the names of every namespace visible to the
+// configured credentials.
+func (c *Client) ListNamespaces(ctx context.Context) ([]string, error) {
+	list, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for _, n := range list.Items {
+		namespaces = append(namespaces, n.Name)
+	}
+	return namespaces, nil
+}
+
+func labelSelector(selectors map[string]string) string {
+	var parts []string
+	for k, v := range selectors {
+		parts = append(parts, fmt.Sprintf("%v=%v", k, v))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ExecPod runs cmd inside container of pod and returns its stdout. On
+// failure, the returned error carries the command's stderr.
+func (c *Client) ExecPod(ctx context.Context, pod, container string, cmd []string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := c.exec(ctx, pod, container, cmd, nil, &stdout, &stderr, false)
+	if err != nil {
+		return stdout.String(), fmt.Errorf("%w: %v", err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// ExecShell opens an interactive shell in path inside container of pod,
+// attached to the given stdin/stdout/stderr, equivalent to `kubectl exec -it`.
+func (c *Client) ExecShell(ctx context.Context, pod, container, path string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := []string{"/bin/sh", "-c", fmt.Sprintf("cd %v && /bin/sh", path)}
+	return c.exec(ctx, pod, container, cmd, stdin, stdout, stderr, true)
+}
+
+func (c *Client) exec(ctx context.Context, pod, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(c.namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+		Tty:    tty,
+	})
+}
+
+// CopyToPod copies the local file at source into destination inside
+// container of pod, equivalent to `kubectl cp`. It streams a tar archive of
+// source through `tar xf -` executed remotely.
+func (c *Client) CopyToPod(ctx context.Context, pod, container, source, destination string) error {
+	var buf bytes.Buffer
+	if err := tarFile(&buf, source, destination); err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd := []string{"tar", "xf", "-", "-C", "/"}
+	if err := c.exec(ctx, pod, container, cmd, &buf, nil, &stderr, false); err != nil {
+		return fmt.Errorf("%w: %v", err, stderr.String())
+	}
+	return nil
+}
+
+func tarFile(w io.Writer, source, destination string) error {
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: strings.TrimPrefix(destination, "/"), Mode: 0755, Size: int64(len(data))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func isOwnedByDeployment(rs *appsv1.ReplicaSet, deployment string) bool {
+	for _, ref := range rs.OwnerReferences {
+		if ref.Kind == "Deployment" && ref.Name == deployment {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package kube
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentRolloutComplete(t *testing.T) {
+	replicas := int32(3)
+	base := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    3,
+			Replicas:           3,
+			AvailableReplicas:  3,
+		},
+	}
+	if !deploymentRolloutComplete(&base) {
+		t.Fatal("expected rollout to be complete")
+	}
+
+	stale := base
+	stale.Status.ObservedGeneration = 1
+	if deploymentRolloutComplete(&stale) {
+		t.Fatal("expected rollout to be incomplete when observedGeneration lags")
+	}
+
+	notYetAvailable := base
+	notYetAvailable.Status.AvailableReplicas = 2
+	if deploymentRolloutComplete(&notYetAvailable) {
+		t.Fatal("expected rollout to be incomplete when availableReplicas is short")
+	}
+
+	noReplicasSet := appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{UpdatedReplicas: 1, Replicas: 1, AvailableReplicas: 1},
+	}
+	if !deploymentRolloutComplete(&noReplicasSet) {
+		t.Fatal("expected unset Replicas to default to 1")
+	}
+}
+
+func TestIsOwnedByDeployment(t *testing.T) {
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "myapp"},
+			},
+		},
+	}
+	if !isOwnedByDeployment(rs, "myapp") {
+		t.Fatal("expected replicaset to be owned by myapp")
+	}
+	if isOwnedByDeployment(rs, "other") {
+		t.Fatal("expected replicaset not to be owned by other")
+	}
+	if isOwnedByDeployment(&appsv1.ReplicaSet{}, "myapp") {
+		t.Fatal("expected replicaset with no owner references not to be owned")
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	got := labelSelector(map[string]string{"app": "myapp"})
+	if got != "app=myapp" {
+		t.Fatalf("expected app=myapp, got %v", got)
+	}
+	if labelSelector(nil) != "" {
+		t.Fatal("expected empty selector for nil map")
+	}
+}
+
+func TestTarFile(t *testing.T) {
+	dir := t.TempDir()
+	source := dir + "/binary"
+	if err := os.WriteFile(source, []byte("debug binary contents"), 0755); err != nil {
+		t.Fatalf("writing source file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tarFile(&buf, source, "/debug/main"); err != nil {
+		t.Fatalf("tarFile: %v", err)
+	}
+
+	tr := tar.NewReader(&buf)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("reading tar header: %v", err)
+	}
+	if hdr.Name != "debug/main" {
+		t.Fatalf("expected header name to have leading slash trimmed, got %v", hdr.Name)
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("reading tar contents: %v", err)
+	}
+	if !strings.Contains(string(data), "debug binary contents") {
+		t.Fatalf("unexpected tar contents: %v", string(data))
+	}
+}
+
+// TestListPodsSortOrder exercises the same sort.Slice comparison ListPods
+// uses, proving pods come back oldest-first rather than in API list order.
+func TestListPodsSortOrder(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "newest", CreationTimestamp: metav1.NewTime(now.Add(2 * time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "oldest", CreationTimestamp: metav1.NewTime(now)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "middle", CreationTimestamp: metav1.NewTime(now.Add(time.Hour))}},
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+	})
+	var names []string
+	for _, p := range items {
+		names = append(names, p.Name)
+	}
+	want := []string{"oldest", "middle", "newest"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, names)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// Stopper stops a running port-forward tunnel.
+type Stopper interface {
+	Stop()
+}
+
+type stopper struct {
+	once   sync.Once
+	stopCh chan struct{}
+}
+
+func (s *stopper) Stop() {
+	s.once.Do(func() { close(s.stopCh) })
+}
+
+// PortForward opens a SPDY tunnel from localAddr to remotePort on pod, the
+// same mechanism `kubectl port-forward` uses under the hood. It blocks until
+// the tunnel is ready, then returns a Stopper the caller uses to shut it
+// down, e.g. on Ctrl-C or when the debug session ends. The tunnel is also
+// torn down if ctx is cancelled first.
+func (c *Client) PortForward(ctx context.Context, pod, localAddr string, remotePort int) (Stopper, error) {
+	host, port, err := net.SplitHostPort(localAddr)
+	if err != nil {
+		host, port = "localhost", localAddr
+	}
+	if host == "" {
+		host = "localhost"
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(c.namespace).
+		Name(pod).
+		SubResource("portforward")
+	transport, upgrader, err := spdy.RoundTripperFor(c.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	ports := []string{fmt.Sprintf("%v:%v", port, remotePort)}
+	out := c.l.Writer()
+	pf, err := portforward.NewOnAddresses(dialer, []string{host}, ports, stopCh, readyCh, out, out)
+	if err != nil {
+		return nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- pf.ForwardPorts() }()
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, fmt.Errorf("port-forward to pod %v failed: %w", pod, err)
+	}
+
+	s := &stopper{stopCh: stopCh}
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+	return s, nil
+}
@@ -0,0 +1,133 @@
+package gograpple
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/foomo/gograpple/kube"
+	"github.com/foomo/squadron"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/apps/v1"
+)
+
+// KubeCmd is the surface Patch and Rollback need from the cluster: deployment
+// reads/patches plus a couple of cluster-native primitives. kube.Client
+// serves it directly through the Kubernetes API; kubectlCmd serves it by
+// shelling out to kubectl, for clusters this process can't reach with an
+// in-cluster or kubeconfig-based rest.Config.
+type KubeCmd interface {
+	GetDeployment(ctx context.Context, deployment string) (*v1.Deployment, error)
+	GetLatestRevision(ctx context.Context, deployment string) (int, error)
+	ValidateContainer(d *v1.Deployment, container *string) error
+	DeleteConfigMap(name string) *kube.Cmd
+	CreateConfigMap(name string, data map[string]string) *kube.Cmd
+	WaitForRollout(deployment, timeout string) *kube.Cmd
+	PatchDeployment(patch, deployment string) *kube.Cmd
+	RolloutUndo(deployment string, toRevision int) *kube.Cmd
+	UpdateChangeCause(deployment, cause string) *kube.Cmd
+}
+
+// NewKubeCmd returns the client-go backed KubeCmd by default, or a
+// kubectl-shelling fallback when kubectlFallback is set. This only covers
+// Patch/Rollback; Debug and Watch always use client-go directly (see
+// Config.KubectlFallback).
+func NewKubeCmd(l *logrus.Entry, cluster, namespace string, kubectlFallback bool) (KubeCmd, error) {
+	if kubectlFallback {
+		return &kubectlCmd{l: l, namespace: namespace}, nil
+	}
+	return kube.NewClient(l, cluster, namespace)
+}
+
+// kubectlCmd implements KubeCmd the way this package always used to: by
+// shelling out to kubectl and parsing its output.
+type kubectlCmd struct {
+	l         *logrus.Entry
+	namespace string
+}
+
+func (k *kubectlCmd) GetDeployment(ctx context.Context, deployment string) (*v1.Deployment, error) {
+	return GetDeployment(ctx, k.l, k.namespace, deployment)
+}
+
+func (k *kubectlCmd) GetLatestRevision(ctx context.Context, deployment string) (int, error) {
+	d, err := k.GetDeployment(ctx, deployment)
+	if err != nil {
+		return 0, err
+	}
+	revision, err := strconv.Atoi(d.Annotations[revisionAnnotation])
+	if err != nil {
+		return 0, fmt.Errorf("reading revision annotation of deployment %v: %w", deployment, err)
+	}
+	return revision, nil
+}
+
+func (k *kubectlCmd) ValidateContainer(d *v1.Deployment, container *string) error {
+	names := getContainers(k.l, d)
+	if *container == "" {
+		if len(names) != 1 {
+			return fmt.Errorf("deployment %v has multiple containers %v, please specify one", d.Name, names)
+		}
+		*container = names[0]
+		return nil
+	}
+	for _, name := range names {
+		if name == *container {
+			return nil
+		}
+	}
+	return fmt.Errorf("container %v not found in deployment %v, available: %v", *container, d.Name, names)
+}
+
+func (k *kubectlCmd) DeleteConfigMap(name string) *kube.Cmd {
+	return kube.NewCmd(k.l, "delete configmap", func(ctx context.Context) (string, error) {
+		cmd := []string{"kubectl", "-n", k.namespace, "delete", "configmap", name, "--ignore-not-found"}
+		return squadron.Command(k.l, cmd...).Run(ctx)
+	})
+}
+
+func (k *kubectlCmd) CreateConfigMap(name string, data map[string]string) *kube.Cmd {
+	return kube.NewCmd(k.l, "create configmap", func(ctx context.Context) (string, error) {
+		cmd := []string{"kubectl", "-n", k.namespace, "create", "configmap", name}
+		for key, value := range data {
+			cmd = append(cmd, "--from-literal", fmt.Sprintf("%v=%v", key, value))
+		}
+		return squadron.Command(k.l, cmd...).Run(ctx)
+	})
+}
+
+func (k *kubectlCmd) WaitForRollout(deployment, timeout string) *kube.Cmd {
+	return kube.NewCmd(k.l, "wait for rollout", func(ctx context.Context) (string, error) {
+		return waitForRollout(k.l, deployment, k.namespace, timeout).Run(ctx)
+	})
+}
+
+func (k *kubectlCmd) PatchDeployment(patch, deployment string) *kube.Cmd {
+	return kube.NewCmd(k.l, "patch deployment", func(ctx context.Context) (string, error) {
+		return patchDeployment(k.l, patch, deployment, k.namespace).Run(ctx)
+	})
+}
+
+func (k *kubectlCmd) RolloutUndo(deployment string, toRevision int) *kube.Cmd {
+	return kube.NewCmd(k.l, "rollout undo", func(ctx context.Context) (string, error) {
+		cmd := []string{
+			"kubectl", "-n", k.namespace,
+			"rollout", "undo", fmt.Sprintf("deployment/%v", deployment),
+			fmt.Sprintf("--to-revision=%v", toRevision),
+		}
+		return squadron.Command(k.l, cmd...).Run(ctx)
+	})
+}
+
+func (k *kubectlCmd) UpdateChangeCause(deployment, cause string) *kube.Cmd {
+	return kube.NewCmd(k.l, "update change-cause", func(ctx context.Context) (string, error) {
+		cmd := []string{
+			"kubectl", "-n", k.namespace,
+			"annotate", fmt.Sprintf("deployment/%v", deployment),
+			fmt.Sprintf("kubernetes.io/change-cause=%v", cause), "--overwrite",
+		}
+		return squadron.Command(k.l, cmd...).Run(ctx)
+	})
+}
+
+const revisionAnnotation = "deployment.kubernetes.io/revision"
@@ -0,0 +1,127 @@
+package gograpple
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// renderDeploymentPatch extracts the embedded deployment-patch template into
+// theHookPath and renders it for container/image/mounts, the same patch
+// Patch applies to a live deployment. It's the sole writer of patchFileName,
+// so Patch relies on it rather than extracting that file itself.
+func (g Grapple) renderDeploymentPatch(container, image string, mounts []Mount) (string, error) {
+	deploymentPatch, err := bindata.ReadFile(filepath.Join(patchFolder, patchFileName))
+	if err != nil {
+		return "", err
+	}
+	theHookPath := path.Join(os.TempDir(), patchFolder)
+	_ = os.Mkdir(theHookPath, perm)
+	if err := os.WriteFile(filepath.Join(theHookPath, patchFileName), deploymentPatch, perm); err != nil {
+		return "", err
+	}
+	return renderTemplate(path.Join(theHookPath, devDeploymentPatchFile), g.newPatchValues(g.deployment.Name, container, image, mounts))
+}
+
+// GenerateManifest renders the same deployment patch Patch would apply and
+// merges it onto the current deployment in memory, writing the result to w
+// as a standalone Deployment manifest suitable for `kubectl apply -f`. This
+// lets teams commit the debug overlay to git, apply it in CI-based preview
+// environments, or diff it before running the interactive patch flow.
+func (g Grapple) GenerateManifest(w io.Writer, container, image string, mounts []Mount) error {
+	patch, err := g.renderDeploymentPatch(container, image, mounts)
+	if err != nil {
+		return err
+	}
+	merged, err := mergeDeploymentPatch(&g.deployment, patch)
+	if err != nil {
+		return err
+	}
+	out, err := marshalManifest(merged)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// marshalManifest renders d as YAML keyed by its json field names
+// (apiVersion, kind, metadata, ...). yaml.v3 ignores the json tags and the
+// inlined TypeMeta of API types, so d goes through encoding/json first and
+// the result is re-encoded as YAML.
+func marshalManifest(d *appsv1.Deployment) ([]byte, error) {
+	js, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(js, &obj); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(obj)
+}
+
+// mergeDeploymentPatch strategic-merges the rendered patch onto original,
+// returning the result as a standalone manifest. original (from a typed
+// client-go Get()) leaves TypeMeta zero-valued, and patch is a kubectl-patch
+// body rather than a full manifest, so neither input carries apiVersion/kind
+// on its own; without setting it explicitly, kubectl apply -f rejects the
+// output with "missing kind".
+func mergeDeploymentPatch(original *appsv1.Deployment, patch string) (*appsv1.Deployment, error) {
+	var patchObj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(patch), &patchObj); err != nil {
+		return nil, fmt.Errorf("parsing rendered patch: %w", err)
+	}
+	patchJSON, err := json.Marshal(patchObj)
+	if err != nil {
+		return nil, err
+	}
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatch(originalJSON, patchJSON, appsv1.Deployment{})
+	if err != nil {
+		return nil, fmt.Errorf("merging patch onto deployment %v: %w", original.Name, err)
+	}
+	var merged appsv1.Deployment
+	if err := json.Unmarshal(mergedJSON, &merged); err != nil {
+		return nil, err
+	}
+	merged.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+	merged.ManagedFields = nil
+	merged.Status = appsv1.DeploymentStatus{}
+	return &merged, nil
+}
+
+// GenerateKustomization writes a kustomization.yaml to w that overlays the
+// rendered deployment patch as a strategic-merge patch over deploymentFile,
+// so the debug overlay can be applied via `kubectl apply -k` alongside the
+// original, unmodified deployment manifest.
+func (g Grapple) GenerateKustomization(w io.Writer, deploymentFile, patchFile string) error {
+	kustomization := struct {
+		APIVersion            string   `yaml:"apiVersion"`
+		Kind                  string   `yaml:"kind"`
+		Resources             []string `yaml:"resources"`
+		PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+	}{
+		APIVersion:            "kustomize.config.k8s.io/v1beta1",
+		Kind:                  "Kustomization",
+		Resources:             []string{deploymentFile},
+		PatchesStrategicMerge: []string{patchFile},
+	}
+	out, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
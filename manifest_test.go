@@ -0,0 +1,58 @@
+package gograpple
+
+import (
+	"bytes"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestMergeDeploymentPatchSetsTypeMeta(t *testing.T) {
+	original := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: "myapp:v1"}},
+				},
+			},
+		},
+	}
+	patch := `
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: myapp:debug
+`
+	merged, err := mergeDeploymentPatch(original, patch)
+	if err != nil {
+		t.Fatalf("mergeDeploymentPatch: %v", err)
+	}
+	if merged.TypeMeta.APIVersion != "apps/v1" || merged.TypeMeta.Kind != "Deployment" {
+		t.Fatalf("expected apiVersion/kind to be set, got %+v", merged.TypeMeta)
+	}
+	if got := merged.Spec.Template.Spec.Containers[0].Image; got != "myapp:debug" {
+		t.Fatalf("expected patched image myapp:debug, got %v", got)
+	}
+
+	rendered, err := marshalManifest(merged)
+	if err != nil {
+		t.Fatalf("marshalling rendered manifest: %v", err)
+	}
+
+	// Decode with apimachinery's own YAML decoder, the same one kubectl apply
+	// relies on, to prove the missing-kind failure mode is actually fixed.
+	var decoded appsv1.Deployment
+	dec := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(rendered), 4096)
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatalf("decoding rendered manifest: %v", err)
+	}
+	if decoded.TypeMeta.APIVersion != "apps/v1" || decoded.TypeMeta.Kind != "Deployment" {
+		t.Fatalf("rendered manifest did not round-trip apiVersion/kind through a real decoder, got %+v", decoded.TypeMeta)
+	}
+}
@@ -15,6 +15,13 @@ var (
 	bindata embed.FS
 )
 
+const (
+	patchFolder    = "the-hook"
+	dockerfileName = "Dockerfile"
+	patchFileName  = "deployment-patch.yaml"
+	perm           = 0700
+)
+
 type Mount struct {
 	HostPath  string
 	MountPath string
@@ -55,7 +62,7 @@ func (g Grapple) Patch(repo, image, platform, container string, mounts []Mount)
 	}
 
 	// check image platform with configured platform
-	imagePlatform, err := g.dockerCmd.ImageInspect("-f", "{{.Os}}/{{.Architecture}}", image).Run(ctx)
+	imagePlatform, err := g.builder.Platform(ctx, image)
 	if err != nil {
 		return err
 	}
@@ -84,21 +91,10 @@ func (g Grapple) Patch(repo, image, platform, container string, mounts []Mount)
 
 	g.l.Infof("extracting patch files")
 
-	const (
-		patchFolder    = "the-hook"
-		dockerfileName = "Dockerfile"
-		patchFileName  = "deployment-patch.yaml"
-		perm           = 0700
-	)
-
 	patchDockerfile, err := bindata.ReadFile(filepath.Join(patchFolder, dockerfileName))
 	if err != nil {
 		return err
 	}
-	deploymentPatch, err := bindata.ReadFile(filepath.Join(patchFolder, patchFileName))
-	if err != nil {
-		return err
-	}
 
 	theHookPath := path.Join(os.TempDir(), patchFolder)
 	_ = os.Mkdir(theHookPath, perm)
@@ -106,17 +102,12 @@ func (g Grapple) Patch(repo, image, platform, container string, mounts []Mount)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(filepath.Join(theHookPath, patchFileName), deploymentPatch, perm)
-	if err != nil {
-		return err
-	}
 
 	patchedImage := g.patchedImageName(repo)
 	completePatchedImage := fmt.Sprintf("%v:%v", patchedImage, defaultTag)
 	g.l.Infof("building patch image %v", completePatchedImage)
-	_, err = g.dockerCmd.Build(theHookPath, "--build-arg",
-		fmt.Sprintf("IMAGE=%v", image), "-t", completePatchedImage,
-		"--platform", platform).Run(ctx)
+	_, err = g.builder.Build(ctx, theHookPath, filepath.Join(theHookPath, dockerfileName), completePatchedImage, platform,
+		map[string]string{"IMAGE": image})
 	if err != nil {
 		return err
 	}
@@ -124,17 +115,14 @@ func (g Grapple) Patch(repo, image, platform, container string, mounts []Mount)
 	if repo != "" {
 		//contains a repo, push the built image
 		g.l.Infof("pushing patch image %v", completePatchedImage)
-		_, err = g.dockerCmd.Push(patchedImage, defaultTag).Run(ctx)
+		_, err = g.builder.Push(ctx, patchedImage, defaultTag)
 		if err != nil {
 			return err
 		}
 	}
 
 	g.l.Infof("rendering deployment patch template")
-	patch, err := renderTemplate(
-		path.Join(theHookPath, devDeploymentPatchFile),
-		g.newPatchValues(g.deployment.Name, container, completePatchedImage, mounts),
-	)
+	patch, err := g.renderDeploymentPatch(container, completePatchedImage, mounts)
 	if err != nil {
 		return err
 	}
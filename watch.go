@@ -0,0 +1,129 @@
+package gograpple
+
+import (
+	"context"
+	"fmt"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchBinaryDestination = "/debug/main"
+
+// restartIn/restartOut mirror the subset of delve's JSON-RPC RPCServer.Restart
+// request/response grapple needs, so a full delve client dependency isn't
+// required just to trigger a restart.
+type restartIn struct {
+	Rebuild bool
+}
+
+type restartOut struct {
+	DiscardedBreakpoints []struct {
+		Name string `json:"name"`
+	} `json:"DiscardedBreakpoints"`
+}
+
+// Watch watches sourcePath for .go file changes and, after debounce of
+// quiet time, rebuilds the delve-instrumented binary, copies it into the
+// patched container and asks the running Delve instance to restart the
+// target. This skips the full docker-build-push-rollout cycle Patch uses
+// for each edit.
+//
+// Like Debug, Watch drives the cluster through g.kube (client-go) directly
+// for ListPods/CopyToPod; it has no kubectl-shelling equivalent, so
+// kubectl_fallback has no effect on it.
+func (g Grapple) Watch(ctx context.Context, sourcePath string, debounce time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watchGoDirs(watcher, sourcePath); err != nil {
+		return err
+	}
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			return err
+		case event := <-watcher.Events:
+			if !strings.HasSuffix(event.Name, ".go") {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(debounce, func() {
+				g.l.Infof("%v changed, rebuilding", event.Name)
+				if err := g.rebuildAndRestart(ctx, sourcePath); err != nil {
+					g.l.Warnf("rebuild failed: %v", err)
+				}
+			})
+		}
+	}
+}
+
+// watchGoDirs adds sourcePath and every directory beneath it to watcher,
+// since fsnotify doesn't watch subdirectories recursively on its own.
+func watchGoDirs(watcher *fsnotify.Watcher, sourcePath string) error {
+	return filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+func (g Grapple) rebuildAndRestart(ctx context.Context, sourcePath string) error {
+	binary := filepath.Join(os.TempDir(), g.deployment.Name+"-debug")
+	build := exec.CommandContext(ctx, "go", "build", "-gcflags", "all=-N -l", "-o", binary, sourcePath)
+	if out, err := build.CombinedOutput(); err != nil {
+		return fmt.Errorf("building %v: %w: %s", sourcePath, err, out)
+	}
+
+	pods, err := g.kube.ListPods(ctx, g.deployment.Spec.Selector.MatchLabels)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no pods found")
+	}
+	pod := pods[len(pods)-1]
+	g.l.Infof("copying rebuilt binary to pod %v", pod)
+	if err := g.kube.CopyToPod(ctx, pod, g.container, binary, watchBinaryDestination); err != nil {
+		return fmt.Errorf("copying binary to pod %v: %w", pod, err)
+	}
+
+	g.l.Info("restarting delve target")
+	return restartDelve(g.listenAddr)
+}
+
+func restartDelve(listenAddr string) error {
+	addr := listenAddr
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	client, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to delve at %v: %w", addr, err)
+	}
+	defer client.Close()
+	var out restartOut
+	return client.Call("RPCServer.Restart", restartIn{Rebuild: false}, &out)
+}